@@ -0,0 +1,284 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// DeliveryStatus is the outcome reported by a delivery status notification (DSN)
+// or a message disposition notification (MDN).
+type DeliveryStatus string
+
+const (
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusDelayed   DeliveryStatus = "delayed"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+	DeliveryStatusRead      DeliveryStatus = "read"
+	DeliveryStatusNotRead   DeliveryStatus = "not_read"
+	// DeliveryStatusUnknown is used for delivery status notifications whose outcome
+	// cannot be told from the subject, e.g. reports from mail systems other than Exchange Online.
+	DeliveryStatusUnknown DeliveryStatus = "unknown"
+)
+
+// DeliveryEvent is a struct that holds the information extracted from a delivery or read report message.
+// OriginalInternetMessageId is the internetMessageId of the message the report refers to,
+// OriginalMessageId is its Graph message ID, if the original message could be found in the mailbox.
+// ReportSender is the From address of the report: the reader for read reports,
+// but usually the postmaster of the reporting mail system for delivery reports.
+type DeliveryEvent struct {
+	Status                    DeliveryStatus
+	ReportMessageId           string
+	OriginalInternetMessageId string
+	OriginalMessageId         string
+	ReportSender              string
+	ReceivedAt                time.Time
+}
+
+// deliveryReportSelect lists the message properties needed to recognize and correlate a report message.
+// internetMessageHeaders is not returned by Graph unless explicitly selected.
+var deliveryReportSelect = []string{"id", "subject", "from", "receivedDateTime", "internetMessageId", "internetMessageHeaders"}
+
+// subjectPrefixes maps the subject prefixes used by Exchange Online for report messages to a DeliveryStatus.
+// They only refine the status of a message already recognized as a report by its headers.
+// The more specific prefixes must come first.
+var subjectPrefixes = []struct {
+	prefix string
+	status DeliveryStatus
+}{
+	{"not read:", DeliveryStatusNotRead},
+	{"read:", DeliveryStatusRead},
+	{"delivered:", DeliveryStatusDelivered},
+	{"relayed:", DeliveryStatusDelivered},
+	{"delivery delayed:", DeliveryStatusDelayed},
+	{"undeliverable:", DeliveryStatusFailed},
+	{"delivery has failed", DeliveryStatusFailed},
+}
+
+// reportSubjectKeywords lists subject fragments commonly used for report messages by other mail systems.
+// Together with subjectPrefixes they are used to skip ordinary mail before fetching its headers.
+var reportSubjectKeywords = []string{
+	"delivery status notification",
+	"delivery report",
+	"mail delivery",
+	"undeliver",
+	"returned mail",
+	"read receipt",
+	"disposition notification",
+}
+
+// GetDeliveryReport is a method on the Service struct.
+// It uses the GraphServiceClient to get the specified message together with its internet message headers.
+// It then checks whether the message is a delivery or read report and, if so, looks up the original message
+// in the mailbox by its internetMessageId.
+// If the original message cannot be found or the lookup fails, the event is returned with an empty OriginalMessageId.
+// It takes a context, a user ID, and a message ID as input.
+// It returns a pointer to a DeliveryEvent, or nil if the message is not a report, and an error.
+func (c *Service) GetDeliveryReport(ctx context.Context, userId string, messageId string) (*DeliveryEvent, error) {
+	config := &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: deliveryReportSelect,
+		},
+	}
+	message, err := c.graph.UsersById(userId).MessagesById(messageId).Get(ctx, config)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	event := ParseDeliveryReport(message)
+	if event == nil || event.OriginalInternetMessageId == "" {
+		return event, nil
+	}
+
+	original, err := c.FindMessageByInternetMessageId(ctx, userId, event.OriginalInternetMessageId)
+	if err == nil && original != nil && original.GetId() != nil {
+		event.OriginalMessageId = *original.GetId()
+	}
+
+	return event, nil
+}
+
+// FindMessageByInternetMessageId is a method on the Service struct.
+// It uses the GraphServiceClient to search the user's mailbox for a message with the specified internetMessageId.
+// It takes a context, a user ID, and an internetMessageId as input.
+// It returns a Messageable, or nil if no message was found, and an error.
+func (c *Service) FindMessageByInternetMessageId(ctx context.Context, userId string, internetMessageId string) (models.Messageable, error) {
+	filter := fmt.Sprintf("internetMessageId eq '%s'", strings.ReplaceAll(internetMessageId, "'", "''"))
+	top := int32(1)
+	config := &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Top:    &top,
+		},
+	}
+	result, err := c.graph.UsersById(userId).Messages().Get(ctx, config)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if len(result.GetValue()) == 0 {
+		return nil, nil
+	}
+
+	return result.GetValue()[0], nil
+}
+
+// GetDeliveryReports is a method on the Service struct.
+// It checks the messages of a batch returned by GetMessagesDelta with GetDeliveryReport
+// and collects the delivery events of the messages that are reports.
+// Delta responses do not include internet message headers, so each candidate message is fetched again.
+// To keep this cheap, messages whose subject does not look like a report subject are skipped,
+// see mayBeDeliveryReport; reports with other subjects can still be checked with GetDeliveryReport.
+// Messages that fail to be fetched, e.g. because they were deleted in the meantime, do not stop the batch.
+// It takes a context, a user ID, and a slice of Messageable as input.
+// It returns a slice of DeliveryEvent and an error joining the errors of the failed messages.
+func (c *Service) GetDeliveryReports(ctx context.Context, userId string, messages []models.Messageable) ([]DeliveryEvent, error) {
+	var events []DeliveryEvent
+	var errs []error
+	for _, message := range messages {
+		if !mayBeDeliveryReport(message) {
+			continue
+		}
+
+		event, err := c.GetDeliveryReport(ctx, userId, *message.GetId())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("message %s: %w", *message.GetId(), err))
+			continue
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// mayBeDeliveryReport is a helper function.
+// It checks the subject of a message, as returned by a delta request, for the prefixes and keywords of report messages.
+// Messages without a subject are kept, as the subject may simply not have been returned.
+// It takes a Messageable as input and returns a boolean.
+func mayBeDeliveryReport(message models.Messageable) bool {
+	if message == nil || message.GetId() == nil || isRemoved(message) {
+		return false
+	}
+	if message.GetSubject() == nil {
+		return true
+	}
+
+	subject := strings.ToLower(strings.TrimSpace(*message.GetSubject()))
+	for _, p := range subjectPrefixes {
+		if strings.HasPrefix(subject, p.prefix) {
+			return true
+		}
+	}
+	for _, k := range reportSubjectKeywords {
+		if strings.Contains(subject, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRemoved is a helper function.
+// It checks if a message returned by a delta request is an "@removed" entry, which only carries the message ID.
+// It takes a Messageable as input and returns a boolean.
+func isRemoved(message models.Messageable) bool {
+	_, ok := message.GetAdditionalData()["@removed"]
+	return ok
+}
+
+// ParseDeliveryReport is a helper function.
+// It checks if the input message is a delivery status notification or a message disposition notification.
+// A message is only considered a report if it has a multipart/report Content-Type header,
+// or an In-Reply-To or Original-Message-ID header together with a report subject prefix used by Exchange Online.
+// The subject prefix refines the status, e.g. to tell a "Not read:" notification from a read one.
+// Delivery status notifications without a known subject prefix get DeliveryStatusUnknown.
+// The original message is identified by the In-Reply-To, Original-Message-ID or References headers,
+// so the message must have been fetched with internetMessageHeaders selected, as GetDeliveryReport does.
+// It takes a Messageable as input and returns a pointer to a DeliveryEvent, or nil if the message is not a report.
+func ParseDeliveryReport(message models.Messageable) *DeliveryEvent {
+	if message == nil {
+		return nil
+	}
+
+	headers := map[string]string{}
+	for _, h := range message.GetInternetMessageHeaders() {
+		if h.GetName() != nil && h.GetValue() != nil {
+			headers[strings.ToLower(*h.GetName())] = *h.GetValue()
+		}
+	}
+
+	var subject string
+	if message.GetSubject() != nil {
+		subject = strings.ToLower(strings.TrimSpace(*message.GetSubject()))
+	}
+
+	var status DeliveryStatus
+	for _, p := range subjectPrefixes {
+		if strings.HasPrefix(subject, p.prefix) {
+			status = p.status
+			break
+		}
+	}
+
+	contentType := strings.ToLower(headers["content-type"])
+	isReport := strings.Contains(contentType, "multipart/report")
+	if !isReport && headers["in-reply-to"] == "" && headers["original-message-id"] == "" {
+		return nil
+	}
+
+	if isReport {
+		switch {
+		case strings.Contains(contentType, "disposition-notification"):
+			if status != DeliveryStatusNotRead {
+				status = DeliveryStatusRead
+			}
+		case strings.Contains(contentType, "delivery-status"):
+			if status == "" || status == DeliveryStatusRead || status == DeliveryStatusNotRead {
+				status = DeliveryStatusUnknown
+			}
+		}
+	}
+
+	if status == "" {
+		return nil
+	}
+
+	event := &DeliveryEvent{
+		Status:                    status,
+		OriginalInternetMessageId: originalInternetMessageId(headers),
+	}
+	if message.GetId() != nil {
+		event.ReportMessageId = *message.GetId()
+	}
+	if from := message.GetFrom(); from != nil && from.GetEmailAddress() != nil && from.GetEmailAddress().GetAddress() != nil {
+		event.ReportSender = *from.GetEmailAddress().GetAddress()
+	}
+	if message.GetReceivedDateTime() != nil {
+		event.ReceivedAt = *message.GetReceivedDateTime()
+	}
+
+	return event
+}
+
+// originalInternetMessageId is a helper function.
+// It returns the internetMessageId of the message a report refers to, taken from the report's headers.
+// It takes a map of lower-cased header names to values as input and returns a string.
+func originalInternetMessageId(headers map[string]string) string {
+	for _, name := range []string{"in-reply-to", "original-message-id"} {
+		if v := strings.TrimSpace(headers[name]); v != "" {
+			return v
+		}
+	}
+
+	if refs := strings.Fields(headers["references"]); len(refs) > 0 {
+		return refs[len(refs)-1]
+	}
+
+	return ""
+}
@@ -0,0 +1,226 @@
+package msgraph
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func newReportMessage(subject string, headers map[string]string) models.Messageable {
+	message := models.NewMessage()
+	message.SetSubject(&subject)
+
+	var hs []models.InternetMessageHeaderable
+	for name, value := range headers {
+		name, value := name, value
+		h := models.NewInternetMessageHeader()
+		h.SetName(&name)
+		h.SetValue(&value)
+		hs = append(hs, h)
+	}
+	message.SetInternetMessageHeaders(hs)
+
+	return message
+}
+
+func TestParseDeliveryReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		subject    string
+		headers    map[string]string
+		wantNil    bool
+		wantStatus DeliveryStatus
+		wantOrigId string
+	}{
+		{
+			name:    "mdn read",
+			subject: "Read: Quarterly report",
+			headers: map[string]string{
+				"Content-Type": "multipart/report; report-type=disposition-notification; boundary=\"b1\"",
+				"In-Reply-To":  "<orig-1@example.com>",
+			},
+			wantStatus: DeliveryStatusRead,
+			wantOrigId: "<orig-1@example.com>",
+		},
+		{
+			name:    "mdn not read",
+			subject: "Not read: Quarterly report",
+			headers: map[string]string{
+				"Content-Type": "multipart/report; report-type=disposition-notification",
+				"In-Reply-To":  "<orig-2@example.com>",
+			},
+			wantStatus: DeliveryStatusNotRead,
+			wantOrigId: "<orig-2@example.com>",
+		},
+		{
+			name:    "dsn failure",
+			subject: "Undeliverable: Quarterly report",
+			headers: map[string]string{
+				"Content-Type":        "multipart/report; report-type=delivery-status",
+				"Original-Message-ID": "<orig-3@example.com>",
+			},
+			wantStatus: DeliveryStatusFailed,
+			wantOrigId: "<orig-3@example.com>",
+		},
+		{
+			name:    "dsn without subject prefix",
+			subject: "Successful Mail Delivery Report",
+			headers: map[string]string{
+				"Content-Type": "multipart/report; report-type=delivery-status",
+			},
+			wantStatus: DeliveryStatusUnknown,
+		},
+		{
+			name:    "dsn delivered",
+			subject: "Delivered: Quarterly report",
+			headers: map[string]string{
+				"Content-Type": "multipart/report; report-type=delivery-status",
+				"In-Reply-To":  "<orig-4@example.com>",
+			},
+			wantStatus: DeliveryStatusDelivered,
+			wantOrigId: "<orig-4@example.com>",
+		},
+		{
+			name:    "references only",
+			subject: "Read: Quarterly report",
+			headers: map[string]string{
+				"Content-Type": "multipart/report; report-type=disposition-notification",
+				"References":   "<thread-1@example.com> <orig-5@example.com>",
+			},
+			wantStatus: DeliveryStatusRead,
+			wantOrigId: "<orig-5@example.com>",
+		},
+		{
+			name:    "exchange read receipt without report content type",
+			subject: "Read: Quarterly report",
+			headers: map[string]string{
+				"In-Reply-To": "<orig-6@example.com>",
+			},
+			wantStatus: DeliveryStatusRead,
+			wantOrigId: "<orig-6@example.com>",
+		},
+		{
+			name:    "non-report subject",
+			subject: "Delivered: your order #123",
+			headers: map[string]string{
+				"Content-Type": "text/html; charset=utf-8",
+			},
+			wantNil: true,
+		},
+		{
+			name:    "reply without report subject",
+			subject: "RE: Quarterly report",
+			headers: map[string]string{
+				"In-Reply-To": "<orig-7@example.com>",
+			},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := ParseDeliveryReport(newReportMessage(tt.subject, tt.headers))
+			if tt.wantNil {
+				if event != nil {
+					t.Fatalf("expected no event, got %+v", event)
+				}
+				return
+			}
+			if event == nil {
+				t.Fatal("expected an event, got nil")
+			}
+			if event.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", event.Status, tt.wantStatus)
+			}
+			if event.OriginalInternetMessageId != tt.wantOrigId {
+				t.Errorf("OriginalInternetMessageId = %q, want %q", event.OriginalInternetMessageId, tt.wantOrigId)
+			}
+		})
+	}
+}
+
+func TestOriginalInternetMessageId(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name: "in-reply-to wins",
+			headers: map[string]string{
+				"in-reply-to":         "<a@example.com>",
+				"original-message-id": "<b@example.com>",
+				"references":          "<c@example.com>",
+			},
+			want: "<a@example.com>",
+		},
+		{
+			name: "original-message-id before references",
+			headers: map[string]string{
+				"original-message-id": "<b@example.com>",
+				"references":          "<c@example.com>",
+			},
+			want: "<b@example.com>",
+		},
+		{
+			name: "last reference",
+			headers: map[string]string{
+				"references": "<c@example.com>\r\n <d@example.com>",
+			},
+			want: "<d@example.com>",
+		},
+		{
+			name:    "none",
+			headers: map[string]string{},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := originalInternetMessageId(tt.headers); got != tt.want {
+				t.Errorf("originalInternetMessageId() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMayBeDeliveryReport(t *testing.T) {
+	removed := models.NewMessage()
+	id := "removed-1"
+	removed.SetId(&id)
+	removed.SetAdditionalData(map[string]interface{}{"@removed": map[string]interface{}{"reason": "deleted"}})
+
+	noSubject := models.NewMessage()
+	noSubject.SetId(&id)
+
+	tests := []struct {
+		name    string
+		message models.Messageable
+		want    bool
+	}{
+		{name: "nil", message: nil, want: false},
+		{name: "removed", message: removed, want: false},
+		{name: "no subject", message: noSubject, want: true},
+		{name: "exchange prefix", message: newDeltaMessage("Undeliverable: Quarterly report"), want: true},
+		{name: "other mail system", message: newDeltaMessage("Delivery Status Notification (Failure)"), want: true},
+		{name: "ordinary mail", message: newDeltaMessage("Lunch on Friday?"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mayBeDeliveryReport(tt.message); got != tt.want {
+				t.Errorf("mayBeDeliveryReport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newDeltaMessage(subject string) models.Messageable {
+	id := "message-1"
+	message := models.NewMessage()
+	message.SetId(&id)
+	message.SetSubject(&subject)
+
+	return message
+}
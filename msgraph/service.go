@@ -71,13 +71,32 @@ func NewService(c Credentials) (*Service, error) {
 // It takes a context, a user ID, and a mail folder ID as input.
 // It returns a pointer to a string and an error.
 func (c *Service) GetMailFolderMessagesDeltaLink(ctx context.Context, userId string, mailFolderId string) (*string, error) {
+	return c.getMailFolderMessagesDeltaLink(ctx, userId, mailFolderId, "{+baseurl}/users/{user%2Did}/mailFolders/{mailFolder%2Did}/messages/microsoft.graph.delta()?changeType=created{?%24top,%24skip,%24search,%24filter,%24count,%24select,%24orderby}")
+}
+
+// GetMailFolderMessagesDeltaLinkWithUpdates is a method on the Service struct.
+// It works like GetMailFolderMessagesDeltaLink, but the returned delta link also reports updated messages,
+// e.g. messages that were flagged after they arrived, and removed messages.
+// Removed messages are returned by GetMessagesDelta as entries with only an ID and "@removed" in their additional data,
+// so callers must not expect the other properties to be set.
+// It takes a context, a user ID, and a mail folder ID as input.
+// It returns a pointer to a string and an error.
+func (c *Service) GetMailFolderMessagesDeltaLinkWithUpdates(ctx context.Context, userId string, mailFolderId string) (*string, error) {
+	return c.getMailFolderMessagesDeltaLink(ctx, userId, mailFolderId, "{+baseurl}/users/{user%2Did}/mailFolders/{mailFolder%2Did}/messages/microsoft.graph.delta(){?%24top,%24skip,%24search,%24filter,%24count,%24select,%24orderby}")
+}
+
+// getMailFolderMessagesDeltaLink is a helper method on the Service struct.
+// It sends the delta request built from the given URL template and skips the existing messages.
+// It takes a context, a user ID, a mail folder ID, and a URL template as input.
+// It returns a pointer to a string and an error.
+func (c *Service) getMailFolderMessagesDeltaLink(ctx context.Context, userId string, mailFolderId string, urlTemplate string) (*string, error) {
 	requestBuilder := c.graph.UsersById(userId).MailFoldersById(mailFolderId).Messages().MicrosoftGraphDelta()
 	ri, err := requestBuilder.ToGetRequestInformation(ctx, nil)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	ri.UrlTemplate = "{+baseurl}/users/{user%2Did}/mailFolders/{mailFolder%2Did}/messages/microsoft.graph.delta()?changeType=created{?%24top,%24skip,%24search,%24filter,%24count,%24select,%24orderby}"
+	ri.UrlTemplate = urlTemplate
 	errorMapping := abstractions.ErrorMappings{
 		"4XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
 		"5XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
@@ -175,9 +194,24 @@ func (c *Service) GetMessage(ctx context.Context, userId string, messageId strin
 // It takes a context, a recipient email, a sender email, a subject, and a content as input.
 // It returns an error.
 func (c *Service) SendMessage(ctx context.Context, to string, from string, subject string, content string) error {
+	return c.SendMessageWithOptions(ctx, to, from, subject, content, SendOptions{})
+}
+
+// SendMessageWithOptions is a method on the Service struct.
+// It works like SendMessage, but additionally applies the given SendOptions to the message,
+// e.g. to request delivery and/or read receipts from the recipient's mail system.
+// It takes a context, a recipient email, a sender email, a subject, a content, and SendOptions as input.
+// It returns an error.
+func (c *Service) SendMessageWithOptions(ctx context.Context, to string, from string, subject string, content string, opts SendOptions) error {
 	requestBody := users.NewItemMicrosoftGraphSendMailSendMailPostRequestBody()
 	message := models.NewMessage()
 	message.SetSubject(&subject)
+	if opts.RequestDeliveryReceipt {
+		message.SetIsDeliveryReceiptRequested(&opts.RequestDeliveryReceipt)
+	}
+	if opts.RequestReadReceipt {
+		message.SetIsReadReceiptRequested(&opts.RequestReadReceipt)
+	}
 
 	ct := models.HTML_BODYTYPE
 	body := models.NewItemBody()
@@ -228,3 +262,9 @@ type FileAttachment struct {
 	ContentType string
 	Content     []byte
 }
+
+// SendOptions is a struct that holds optional settings for sending a message.
+type SendOptions struct {
+	RequestDeliveryReceipt bool
+	RequestReadReceipt     bool
+}
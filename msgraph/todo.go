@@ -0,0 +1,230 @@
+package msgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// TaskList is a struct that holds the ID and display name of a To Do task list,
+// and whether it is the user's default task list.
+type TaskList struct {
+	Id          string
+	DisplayName string
+	IsDefault   bool
+}
+
+// Task is a struct that holds the fields of a To Do task to create.
+// If LinkUrl or LinkExternalId is set, the task gets a linked resource, e.g. pointing to a message.
+// LinkExternalId is what TaskExistsForMessage looks for, so it is set even if there is no LinkUrl.
+type Task struct {
+	Title           string
+	Content         string
+	DueDateTime     *time.Time
+	LinkUrl         string
+	LinkDisplayName string
+	LinkExternalId  string
+}
+
+// MessageFilter is a function that decides whether a task should be created for a message
+// in addition to the flagged ones.
+type MessageFilter func(message models.Messageable) bool
+
+// ListTaskLists is a method on the Service struct.
+// It uses the GraphServiceClient to create a request to get the To Do task lists of the specified user.
+// It then sends the request and returns the task lists.
+// It takes a context and a user ID as input.
+// It returns a slice of TaskList and an error.
+func (c *Service) ListTaskLists(ctx context.Context, userId string) ([]TaskList, error) {
+	result, err := c.graph.UsersById(userId).Todo().Lists().Get(ctx, nil)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	var lists []TaskList
+	for _, l := range result.GetValue() {
+		list := TaskList{}
+		if l.GetId() != nil {
+			list.Id = *l.GetId()
+		}
+		if l.GetDisplayName() != nil {
+			list.DisplayName = *l.GetDisplayName()
+		}
+		if l.GetWellknownListName() != nil {
+			list.IsDefault = *l.GetWellknownListName() == models.DEFAULTLIST_WELLKNOWNLISTNAME
+		}
+
+		lists = append(lists, list)
+	}
+
+	return lists, nil
+}
+
+// CreateTask is a method on the Service struct.
+// It uses the GraphServiceClient to create a request to create a new task in the specified To Do task list.
+// It then sends the request and returns the ID of the created task.
+// It takes a context, a user ID, a task list ID, and a Task as input.
+// It returns a string and an error.
+func (c *Service) CreateTask(ctx context.Context, userId string, listId string, task Task) (string, error) {
+	return c.postTask(ctx, userId, listId, newTodoTask(task))
+}
+
+// TaskExistsForMessage is a method on the Service struct.
+// It uses the GraphServiceClient to search the specified To Do task list for a task
+// with a linked resource pointing to the specified message, i.e. one created by CreateTaskFromMessage.
+// It takes a context, a user ID, a task list ID, and a message ID as input.
+// It returns a boolean and an error.
+func (c *Service) TaskExistsForMessage(ctx context.Context, userId string, listId string, messageId string) (bool, error) {
+	filter := fmt.Sprintf("linkedResources/any(r: r/externalId eq '%s')", strings.ReplaceAll(messageId, "'", "''"))
+	top := int32(1)
+	config := &users.ItemTodoListsItemTasksRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemTodoListsItemTasksRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Top:    &top,
+		},
+	}
+	result, err := c.graph.UsersById(userId).Todo().ListsById(listId).Tasks().Get(ctx, config)
+	if err != nil {
+		return false, parseError(err)
+	}
+
+	return len(result.GetValue()) > 0, nil
+}
+
+// CreateTaskFromMessage is a method on the Service struct.
+// It creates a task in the specified To Do task list if the message is flagged or matches the filter.
+// The task uses the message subject as title, the body preview as content, the flag's due date,
+// and links back to the message. No task is created if the list already has a task for the message.
+// Messages that get flagged after they arrive are only seen with a delta link
+// from GetMailFolderMessagesDeltaLinkWithUpdates; the "@removed" entries it also returns are skipped.
+// It takes a context, a user ID, a task list ID, a Messageable, and an optional MessageFilter as input.
+// It returns the ID of the created task, or an empty string if no task was created, and an error.
+func (c *Service) CreateTaskFromMessage(ctx context.Context, userId string, listId string, message models.Messageable, filter MessageFilter) (string, error) {
+	if !shouldCreateTask(message, filter) {
+		return "", nil
+	}
+
+	exists, err := c.TaskExistsForMessage(ctx, userId, listId, *message.GetId())
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", nil
+	}
+
+	return c.postTask(ctx, userId, listId, newTodoTaskFromMessage(message))
+}
+
+// shouldCreateTask is a helper function.
+// It checks if a task should be created for a message: it must be flagged or match the optional filter.
+// Nil messages, messages without an ID, and "@removed" delta entries never match.
+// It takes a Messageable and a MessageFilter as input and returns a boolean.
+func shouldCreateTask(message models.Messageable, filter MessageFilter) bool {
+	if message == nil || message.GetId() == nil || isRemoved(message) {
+		return false
+	}
+
+	return IsFlagged(message) || (filter != nil && filter(message))
+}
+
+// IsFlagged is a MessageFilter.
+// It returns true if the message is flagged for follow-up.
+func IsFlagged(message models.Messageable) bool {
+	if message == nil || message.GetFlag() == nil || message.GetFlag().GetFlagStatus() == nil {
+		return false
+	}
+
+	return *message.GetFlag().GetFlagStatus() == models.FLAGGED_FOLLOWUPFLAGSTATUS
+}
+
+// newTodoTask is a helper function.
+// It builds the request body for a new To Do task.
+// It takes a Task as input and returns a TodoTaskable.
+func newTodoTask(task Task) models.TodoTaskable {
+	requestBody := models.NewTodoTask()
+	requestBody.SetTitle(&task.Title)
+
+	if task.Content != "" {
+		ct := models.TEXT_BODYTYPE
+		body := models.NewItemBody()
+		body.SetContentType(&ct)
+		body.SetContent(&task.Content)
+		requestBody.SetBody(body)
+	}
+
+	if task.DueDateTime != nil {
+		dt := task.DueDateTime.UTC().Format("2006-01-02T15:04:05")
+		tz := "UTC"
+		due := models.NewDateTimeTimeZone()
+		due.SetDateTime(&dt)
+		due.SetTimeZone(&tz)
+		requestBody.SetDueDateTime(due)
+	}
+
+	if task.LinkUrl != "" || task.LinkExternalId != "" {
+		appName := "office-365-listener"
+		link := models.NewLinkedResource()
+		link.SetApplicationName(&appName)
+		if task.LinkUrl != "" {
+			link.SetWebUrl(&task.LinkUrl)
+		}
+		if task.LinkDisplayName != "" {
+			link.SetDisplayName(&task.LinkDisplayName)
+		}
+		if task.LinkExternalId != "" {
+			link.SetExternalId(&task.LinkExternalId)
+		}
+		requestBody.SetLinkedResources([]models.LinkedResourceable{link})
+	}
+
+	return requestBody
+}
+
+// newTodoTaskFromMessage is a helper function.
+// It builds the request body for a task linking back to the message.
+// The flag's due date is passed on as is, keeping its time zone.
+// It takes a Messageable with an ID as input and returns a TodoTaskable.
+func newTodoTaskFromMessage(message models.Messageable) models.TodoTaskable {
+	task := Task{
+		Title:           "(no subject)",
+		LinkDisplayName: "Open message",
+		LinkExternalId:  *message.GetId(),
+	}
+	if message.GetSubject() != nil && *message.GetSubject() != "" {
+		task.Title = *message.GetSubject()
+	}
+	if message.GetBodyPreview() != nil {
+		task.Content = *message.GetBodyPreview()
+	}
+	if message.GetWebLink() != nil {
+		task.LinkUrl = *message.GetWebLink()
+	}
+
+	requestBody := newTodoTask(task)
+	if flag := message.GetFlag(); flag != nil && flag.GetDueDateTime() != nil {
+		requestBody.SetDueDateTime(flag.GetDueDateTime())
+	}
+
+	return requestBody
+}
+
+// postTask is a helper method on the Service struct.
+// It sends the request to create the task in the specified To Do task list.
+// It takes a context, a user ID, a task list ID, and a TodoTaskable as input.
+// It returns the ID of the created task and an error.
+func (c *Service) postTask(ctx context.Context, userId string, listId string, requestBody models.TodoTaskable) (string, error) {
+	result, err := c.graph.UsersById(userId).Todo().ListsById(listId).Tasks().Post(ctx, requestBody, nil)
+	if err != nil {
+		return "", parseError(err)
+	}
+	if result.GetId() == nil {
+		return "", errors.New("created task has no ID")
+	}
+
+	return *result.GetId(), nil
+}
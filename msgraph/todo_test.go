@@ -0,0 +1,217 @@
+package msgraph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func newTodoMessage(id string, subject string, webLink string, flagged bool) models.Messageable {
+	message := models.NewMessage()
+	if id != "" {
+		message.SetId(&id)
+	}
+	if subject != "" {
+		message.SetSubject(&subject)
+	}
+	if webLink != "" {
+		message.SetWebLink(&webLink)
+	}
+	if flagged {
+		status := models.FLAGGED_FOLLOWUPFLAGSTATUS
+		flag := models.NewFollowupFlag()
+		flag.SetFlagStatus(&status)
+		message.SetFlag(flag)
+	}
+
+	return message
+}
+
+func TestShouldCreateTask(t *testing.T) {
+	removed := newTodoMessage("message-1", "", "", true)
+	removed.SetAdditionalData(map[string]interface{}{"@removed": map[string]interface{}{"reason": "deleted"}})
+
+	matchAll := func(models.Messageable) bool { return true }
+	matchNone := func(models.Messageable) bool { return false }
+
+	tests := []struct {
+		name    string
+		message models.Messageable
+		filter  MessageFilter
+		want    bool
+	}{
+		{name: "nil message", message: nil, filter: matchAll, want: false},
+		{name: "no id", message: newTodoMessage("", "Subject", "", true), filter: matchAll, want: false},
+		{name: "removed", message: removed, filter: matchAll, want: false},
+		{name: "flagged without filter", message: newTodoMessage("message-1", "Subject", "", true), filter: nil, want: true},
+		{name: "flagged with non-matching filter", message: newTodoMessage("message-1", "Subject", "", true), filter: matchNone, want: true},
+		{name: "not flagged without filter", message: newTodoMessage("message-1", "Subject", "", false), filter: nil, want: false},
+		{name: "not flagged with matching filter", message: newTodoMessage("message-1", "Subject", "", false), filter: matchAll, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldCreateTask(tt.message, tt.filter); got != tt.want {
+				t.Errorf("shouldCreateTask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTodoTask(t *testing.T) {
+	due := time.Date(2023, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name        string
+		task        Task
+		wantLink    bool
+		wantWebUrl  string
+		wantExtId   string
+		wantDueTime string
+	}{
+		{
+			name:     "no link",
+			task:     Task{Title: "Task"},
+			wantLink: false,
+		},
+		{
+			name:       "link with url and external id",
+			task:       Task{Title: "Task", LinkUrl: "https://outlook.office.com/mail/1", LinkExternalId: "message-1"},
+			wantLink:   true,
+			wantWebUrl: "https://outlook.office.com/mail/1",
+			wantExtId:  "message-1",
+		},
+		{
+			name:      "external id without url",
+			task:      Task{Title: "Task", LinkExternalId: "message-1"},
+			wantLink:  true,
+			wantExtId: "message-1",
+		},
+		{
+			name:        "due date in utc",
+			task:        Task{Title: "Task", DueDateTime: &due},
+			wantDueTime: "2023-03-01T08:30:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTodoTask(tt.task)
+			if got.GetTitle() == nil || *got.GetTitle() != tt.task.Title {
+				t.Errorf("Title = %v, want %q", got.GetTitle(), tt.task.Title)
+			}
+
+			links := got.GetLinkedResources()
+			if !tt.wantLink {
+				if len(links) != 0 {
+					t.Errorf("expected no linked resources, got %d", len(links))
+				}
+			} else {
+				if len(links) != 1 {
+					t.Fatalf("expected 1 linked resource, got %d", len(links))
+				}
+				link := links[0]
+				if link.GetApplicationName() == nil {
+					t.Error("ApplicationName is not set")
+				}
+				if v := stringValue(link.GetWebUrl()); v != tt.wantWebUrl {
+					t.Errorf("WebUrl = %q, want %q", v, tt.wantWebUrl)
+				}
+				if v := stringValue(link.GetExternalId()); v != tt.wantExtId {
+					t.Errorf("ExternalId = %q, want %q", v, tt.wantExtId)
+				}
+			}
+
+			if tt.wantDueTime != "" {
+				if got.GetDueDateTime() == nil {
+					t.Fatal("DueDateTime is not set")
+				}
+				if v := stringValue(got.GetDueDateTime().GetDateTime()); v != tt.wantDueTime {
+					t.Errorf("DueDateTime = %q, want %q", v, tt.wantDueTime)
+				}
+				if v := stringValue(got.GetDueDateTime().GetTimeZone()); v != "UTC" {
+					t.Errorf("TimeZone = %q, want %q", v, "UTC")
+				}
+			}
+		})
+	}
+}
+
+func TestNewTodoTaskFromMessage(t *testing.T) {
+	withDue := newTodoMessage("message-2", "Follow up", "", true)
+	dt, tz := "2023-03-01T00:00:00.0000000", "W. Europe Standard Time"
+	due := models.NewDateTimeTimeZone()
+	due.SetDateTime(&dt)
+	due.SetTimeZone(&tz)
+	withDue.GetFlag().SetDueDateTime(due)
+
+	tests := []struct {
+		name      string
+		message   models.Messageable
+		wantTitle string
+		wantExtId string
+		wantDue   bool
+	}{
+		{
+			name:      "subject and web link",
+			message:   newTodoMessage("message-1", "Invoice", "https://outlook.office.com/mail/1", true),
+			wantTitle: "Invoice",
+			wantExtId: "message-1",
+		},
+		{
+			name:      "no subject and no web link",
+			message:   newTodoMessage("message-1", "", "", true),
+			wantTitle: "(no subject)",
+			wantExtId: "message-1",
+		},
+		{
+			name:      "flag due date",
+			message:   withDue,
+			wantTitle: "Follow up",
+			wantExtId: "message-2",
+			wantDue:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTodoTaskFromMessage(tt.message)
+			if title := stringValue(got.GetTitle()); title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", title, tt.wantTitle)
+			}
+
+			links := got.GetLinkedResources()
+			if len(links) != 1 {
+				t.Fatalf("expected 1 linked resource, got %d", len(links))
+			}
+			if extId := stringValue(links[0].GetExternalId()); extId != tt.wantExtId {
+				t.Errorf("ExternalId = %q, want %q", extId, tt.wantExtId)
+			}
+
+			if !tt.wantDue {
+				if got.GetDueDateTime() != nil {
+					t.Error("expected no DueDateTime")
+				}
+				return
+			}
+			if got.GetDueDateTime() == nil {
+				t.Fatal("DueDateTime is not set")
+			}
+			if v := stringValue(got.GetDueDateTime().GetDateTime()); v != dt {
+				t.Errorf("DueDateTime = %q, want %q", v, dt)
+			}
+			if v := stringValue(got.GetDueDateTime().GetTimeZone()); v != tz {
+				t.Errorf("TimeZone = %q, want %q", v, tz)
+			}
+		})
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}